@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// csvFormatter renders rows as CSV, using the sorted keys of the first row
+// (plus export_date) as the header so the column order is stable.
+type csvFormatter struct{}
+
+func (csvFormatter) Format(rows []json.RawMessage, date string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if len(rows) == 0 {
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+
+	first, err := decodeRow(rows[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode row for csv: %v", err)
+	}
+
+	columns := make([]string, 0, len(first)+1)
+	for k := range first {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+	columns = append(columns, "export_date")
+
+	if err := w.Write(columns); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %v", err)
+	}
+
+	for _, row := range rows {
+		fields, err := decodeRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode row for csv: %v", err)
+		}
+		fields["export_date"] = date
+
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = fmt.Sprintf("%v", fields[col])
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write csv row: %v", err)
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (csvFormatter) Extension() string { return "csv" }
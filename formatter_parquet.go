@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetFormatter renders rows as Parquet using a flat, all-UTF8 schema
+// derived from the first row's keys. The exported views don't carry a fixed
+// Go struct, so every column is written as a string rather than attempting
+// to infer numeric/date types per view.
+type parquetFormatter struct{}
+
+func (parquetFormatter) Format(rows []json.RawMessage, date string) ([]byte, error) {
+	columns := []string{"export_date"}
+	if len(rows) > 0 {
+		first, err := decodeRow(rows[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode row for parquet: %v", err)
+		}
+		for k := range first {
+			columns = append(columns, k)
+		}
+	}
+
+	sink := buffer.NewBufferFile()
+	pw, err := writer.NewJSONWriter(buildParquetSchema(columns), sink, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %v", err)
+	}
+
+	for _, row := range rows {
+		fields, err := decodeRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode row for parquet: %v", err)
+		}
+		fields["export_date"] = date
+
+		line, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal parquet row: %v", err)
+		}
+		if err := pw.Write(string(line)); err != nil {
+			return nil, fmt.Errorf("failed to write parquet row: %v", err)
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		return nil, fmt.Errorf("failed to finalize parquet file: %v", err)
+	}
+
+	return sink.Bytes(), nil
+}
+
+func (parquetFormatter) Extension() string { return "parquet" }
+
+// buildParquetSchema builds the JSON schema the parquet-go JSON writer
+// expects, treating every column as an optional UTF8 byte array.
+func buildParquetSchema(columns []string) string {
+	fields := make([]string, 0, len(columns))
+	for _, col := range columns {
+		fields = append(fields, fmt.Sprintf(`{"Tag":"name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"}`, col))
+	}
+	return fmt.Sprintf(`{"Tag":"name=parquet_go_root, repetitiontype=REQUIRED","Fields":[%s]}`, strings.Join(fields, ","))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Formatter renders a view's rows into a specific output format.
+type Formatter interface {
+	// Format renders rows (each a raw JSON object from json_agg) into the
+	// formatter's output format.
+	Format(rows []json.RawMessage, date string) ([]byte, error)
+	// Extension returns the file extension used for this format, without a
+	// leading dot.
+	Extension() string
+}
+
+// newFormatter selects a Formatter by name. An empty format defaults to
+// "json" to match the exporter's historical output.
+func newFormatter(format string) (Formatter, error) {
+	switch format {
+	case "", "json":
+		return jsonFormatter{}, nil
+	case "ndjson":
+		return ndjsonFormatter{}, nil
+	case "csv":
+		return csvFormatter{}, nil
+	case "parquet":
+		return parquetFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// jsonFormatter wraps rows in the exporter's historical {data, export_date}
+// envelope, pretty-printed.
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(rows []json.RawMessage, date string) ([]byte, error) {
+	wrapper := map[string]interface{}{
+		"data":        rows,
+		"export_date": date,
+	}
+
+	data, err := json.Marshal(wrapper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json export: %v", err)
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, data, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to indent json export: %v", err)
+	}
+	return pretty.Bytes(), nil
+}
+
+func (jsonFormatter) Extension() string { return "json" }
+
+// ndjsonFormatter writes one JSON object per line (newline-delimited JSON),
+// with export_date stamped onto each row, for streaming into systems like
+// BigQuery or ClickHouse.
+type ndjsonFormatter struct{}
+
+func (ndjsonFormatter) Format(rows []json.RawMessage, date string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, row := range rows {
+		fields, err := decodeRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode row for ndjson: %v", err)
+		}
+		fields["export_date"] = date
+
+		line, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ndjson row: %v", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func (ndjsonFormatter) Extension() string { return "ndjson" }
+
+// decodeRow unmarshals a single json_agg row into a field map.
+func decodeRow(row json.RawMessage) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(row, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
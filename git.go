@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// Repository wraps a go-git repository rooted at the export output
+// directory, so the exporter no longer depends on a git binary being
+// present in the deployment environment.
+type Repository struct {
+	repo *git.Repository
+	path string
+}
+
+// OpenRepository opens the git repository containing path, searching
+// parent directories for the .git folder the same way the git CLI does.
+func OpenRepository(path string) (*Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %v", path, err)
+	}
+	return &Repository{repo: repo, path: path}, nil
+}
+
+// Commit stages every change under the repository's worktree and commits
+// it with the given message and author identity. It reports committed as
+// false (with a nil error) when there was nothing to commit.
+func (r *Repository) Commit(msg, author, email string) (committed bool, err error) {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree: %v", err)
+	}
+
+	if err := wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return false, fmt.Errorf("git add failed: %v", err)
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree status: %v", err)
+	}
+	if status.IsClean() {
+		return false, nil
+	}
+
+	_, err = wt.Commit(msg, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  author,
+			Email: email,
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("git commit failed: %v", err)
+	}
+	return true, nil
+}
+
+// Push pushes branch to remote using auth. A nil auth falls back to the
+// system git binary, which picks up credential helpers or SSH agent state
+// configured outside the Go process.
+func (r *Repository) Push(remote, branch string, auth transport.AuthMethod) error {
+	if auth == nil {
+		return pushWithSystemGit(r.path, remote, branch)
+	}
+
+	err := r.repo.Push(&git.PushOptions{
+		RemoteName: remote,
+		Auth:       auth,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("git push failed: %v", err)
+	}
+	return nil
+}
+
+// authFromEnv builds a transport.AuthMethod from environment variables,
+// preferring an SSH key (GIT_SSH_KEY, optionally GIT_SSH_KEY_PASSPHRASE)
+// over an HTTP token (GIT_HTTP_USERNAME/GIT_HTTP_TOKEN). It returns a nil
+// AuthMethod and nil error when neither is configured, signalling callers
+// to fall back to the system git binary.
+func authFromEnv() (transport.AuthMethod, error) {
+	if keyPath := os.Getenv("GIT_SSH_KEY"); keyPath != "" {
+		publicKeys, err := gitssh.NewPublicKeysFromFile("git", keyPath, os.Getenv("GIT_SSH_KEY_PASSPHRASE"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSH key %s: %v", keyPath, err)
+		}
+		return publicKeys, nil
+	}
+
+	if username, token := os.Getenv("GIT_HTTP_USERNAME"), os.Getenv("GIT_HTTP_TOKEN"); token != "" {
+		return &githttp.BasicAuth{Username: username, Password: token}, nil
+	}
+
+	return nil, nil
+}
+
+// pushWithSystemGit shells out to the git binary, used only when no auth is
+// configured via environment variables.
+func pushWithSystemGit(repoPath, remote, branch string) error {
+	cmd := exec.Command("git", "-C", repoPath, "push", remote, branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git push failed: %v, output: %s", err, output)
+	}
+	return nil
+}
@@ -1,14 +1,12 @@
 package main
 
 import (
-	"bytes"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -17,19 +15,27 @@ import (
 
 // Config holds database connection parameters
 type Config struct {
-	Host      string
-	Port      int
-	User      string
-	Password  string
-	DBName    string
-	SSLMode   string
-	OutputDir string
+	Host        string
+	Port        int
+	User        string
+	Password    string
+	DBName      string
+	SSLMode     string
+	OutputDir   string
+	StorageAddr string
+	MetricsAddr string
+	ViewsFile   string
+	GitRemote   string
+	GitBranch   string
+	AuthorName  string
+	AuthorEmail string
 }
 
 // loadConfig loads configuration from environment variables
 func loadConfig() (Config, error) {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
+	// Load .env file if present; unattended deployments (Kubernetes, CI)
+	// configure everything through real env vars and have no .env on disk.
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
 		return Config{}, fmt.Errorf("error loading .env file: %v", err)
 	}
 
@@ -39,15 +45,24 @@ func loadConfig() (Config, error) {
 		return Config{}, fmt.Errorf("invalid DB_PORT: %v", err)
 	}
 
+	outputDir := getEnvWithDefault("OUTPUT_DIR", "data_exports")
+
 	// Create config from environment variables
 	config := Config{
-		Host:      getEnvWithDefault("DB_HOST", "localhost"),
-		Port:      port,
-		User:      getEnvWithDefault("DB_USER", "postgres"),
-		Password:  getEnvWithDefault("DB_PASSWORD", ""),
-		DBName:    getEnvWithDefault("DB_NAME", "hospital_db"),
-		SSLMode:   getEnvWithDefault("DB_SSLMODE", "disable"),
-		OutputDir: getEnvWithDefault("OUTPUT_DIR", "data_exports"),
+		Host:        getEnvWithDefault("DB_HOST", "localhost"),
+		Port:        port,
+		User:        getEnvWithDefault("DB_USER", "postgres"),
+		Password:    getEnvWithDefault("DB_PASSWORD", ""),
+		DBName:      getEnvWithDefault("DB_NAME", "hospital_db"),
+		SSLMode:     getEnvWithDefault("DB_SSLMODE", "disable"),
+		OutputDir:   outputDir,
+		StorageAddr: getEnvWithDefault("STORAGE_ADDR", outputDir),
+		MetricsAddr: getEnvWithDefault("METRICS_ADDR", ""),
+		ViewsFile:   getEnvWithDefault("VIEWS_FILE", "views.yaml"),
+		GitRemote:   getEnvWithDefault("GIT_REMOTE", "origin"),
+		GitBranch:   getEnvWithDefault("GIT_BRANCH", "main"),
+		AuthorName:  getEnvWithDefault("GIT_AUTHOR_NAME", "wait-times-exporter"),
+		AuthorEmail: getEnvWithDefault("GIT_AUTHOR_EMAIL", "wait-times-exporter@localhost"),
 	}
 
 	return config, nil
@@ -69,146 +84,212 @@ func main() {
 		return
 	}
 
+	serveMetrics(config.MetricsAddr)
+
+	if isServeMode() {
+		if err := runServe(config); err != nil {
+			fmt.Printf("Error running scheduler: %v\n", err)
+		}
+		return
+	}
+
+	if err := runExport(config); err != nil {
+		fmt.Printf("%v\n", err)
+	}
+}
+
+// isServeMode reports whether the exporter should run as a long-lived
+// scheduler (--serve flag or MODE=cron) instead of a one-shot export.
+func isServeMode() bool {
+	for _, arg := range os.Args[1:] {
+		if arg == "--serve" {
+			return true
+		}
+	}
+	return getEnvWithDefault("MODE", "") == "cron"
+}
+
+// runExport connects to the database, exports each view to the configured
+// storage backend, and commits/pushes the result. It is the single export
+// run used both by the one-shot CLI path and by the scheduler.
+func runExport(config Config) (err error) {
+	start := time.Now()
+	defer func() {
+		exportLastDurationSeconds.Set(time.Since(start).Seconds())
+		if err != nil {
+			exportRunsTotal.WithLabelValues("error").Inc()
+			return
+		}
+		exportRunsTotal.WithLabelValues("ok").Inc()
+		exportLastSuccessTimestamp.Set(float64(time.Now().Unix()))
+	}()
+
 	// Connect to the database
 	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
 
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		fmt.Printf("Error connecting to database: %v\n", err)
-		return
+		return fmt.Errorf("error connecting to database: %v", err)
 	}
 	defer db.Close()
 
 	// Test the connection
-	err = db.Ping()
-	if err != nil {
-		fmt.Printf("Error pinging database: %v\n", err)
-		return
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("error pinging database: %v", err)
 	}
 	fmt.Println("Successfully connected to the database")
 
-	// Create output directory
-	err = os.MkdirAll(config.OutputDir, 0755)
+	// Create output directory (also the git working tree for the export push)
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %v", err)
+	}
+
+	// Select the blob-storage backend (local, S3 or GCS) for writing exports
+	store, err := NewStorage(config.StorageAddr)
 	if err != nil {
-		fmt.Printf("Error creating output directory: %v\n", err)
-		return
+		return fmt.Errorf("error initializing storage backend: %v", err)
 	}
 
-	// Current date for commit message and JSON data
+	// Current date for commit message and partitioned filenames
 	currentDate := time.Now().Format("2006-01-02")
 
-	// Views to query
-	views := []string{
-		"hospital_seven_avg_change",
-		"daily_wait_time_stats",
-		"monthly_avg_wait_times",
+	// Views to query, loaded from ViewsFile (falls back to defaultViews)
+	views, err := loadViews(config.ViewsFile)
+	if err != nil {
+		return fmt.Errorf("error loading views config: %v", err)
 	}
 
-	// Query each view and save to JSON
-	for _, view := range views {
-		// Query the view
+	retention, err := loadRetentionConfig()
+	if err != nil {
+		return fmt.Errorf("error loading retention config: %v", err)
+	}
+
+	var summaries []viewExportSummary
+
+	// Query each view and write it out in its configured format
+	for _, vc := range views {
 		var jsonData []byte
-		query := fmt.Sprintf("SELECT json_agg(t) FROM (SELECT * FROM %s) t", view)
+		query := fmt.Sprintf("SELECT json_agg(t) FROM (SELECT * FROM %s) t", vc.Name)
 
 		err = db.QueryRow(query).Scan(&jsonData)
 		if err != nil {
-			fmt.Printf("Error querying view %s: %v\n", view, err)
+			fmt.Printf("Error querying view %s: %v\n", vc.Name, err)
+			exportViewErrorsTotal.WithLabelValues(vc.Name).Inc()
 			continue
 		}
 
-		// If null result, create an empty array
+		// If null result, treat it as an empty array
 		if jsonData == nil {
 			jsonData = []byte("[]")
 		}
 
-		// Add export date field
-		jsonData, err = addExportDateToJSON(jsonData, currentDate)
+		var rows []json.RawMessage
+		if err := json.Unmarshal(jsonData, &rows); err != nil {
+			fmt.Printf("Error parsing rows for %s: %v\n", vc.Name, err)
+			exportViewErrorsTotal.WithLabelValues(vc.Name).Inc()
+			continue
+		}
+
+		formatter, err := newFormatter(vc.Format)
 		if err != nil {
-			fmt.Printf("Error adding date to JSON for %s: %v\n", view, err)
+			fmt.Printf("Error selecting formatter for %s: %v\n", vc.Name, err)
+			exportViewErrorsTotal.WithLabelValues(vc.Name).Inc()
 			continue
 		}
 
-		// Format the JSON for better readability
-		var prettyJSON bytes.Buffer
-		err = json.Indent(&prettyJSON, jsonData, "", "  ")
+		output, err := formatter.Format(rows, currentDate)
 		if err != nil {
-			fmt.Printf("Error formatting JSON for %s: %v\n", view, err)
+			fmt.Printf("Error formatting %s: %v\n", vc.Name, err)
+			exportViewErrorsTotal.WithLabelValues(vc.Name).Inc()
 			continue
 		}
 
-		// Save to file
-		filename := filepath.Join(config.OutputDir, fmt.Sprintf("%s.json", view))
-		err = os.WriteFile(filename, prettyJSON.Bytes(), 0644)
+		filename, err := renderObjectName(vc, currentDate, formatter.Extension())
 		if err != nil {
-			fmt.Printf("Error writing file %s: %v\n", filename, err)
+			fmt.Printf("Error naming export for %s: %v\n", vc.Name, err)
+			exportViewErrorsTotal.WithLabelValues(vc.Name).Inc()
+			continue
+		}
+
+		if err := store.Write(filename, output); err != nil {
+			fmt.Printf("Error writing export %s: %v\n", filename, err)
+			exportViewErrorsTotal.WithLabelValues(vc.Name).Inc()
+			continue
+		}
+		if err := store.Write(latestObjectName(vc, formatter.Extension()), output); err != nil {
+			fmt.Printf("Error writing latest copy for %s: %v\n", vc.Name, err)
+			exportViewErrorsTotal.WithLabelValues(vc.Name).Inc()
 			continue
 		}
 
-		fmt.Printf("Successfully exported %s to %s\n", view, filename)
+		if err := pruneExpiredExports(store, vc, formatter.Extension(), retention, time.Now()); err != nil {
+			fmt.Printf("Error pruning old exports for %s: %v\n", vc.Name, err)
+		}
+
+		exportRowsTotal.WithLabelValues(vc.Name).Add(float64(len(rows)))
+		summaries = append(summaries, viewExportSummary{Name: vc.Name, Rows: len(rows)})
+		fmt.Printf("Successfully exported %s to %s\n", vc.Name, filename)
 	}
 
 	// Git operations
-	if err := gitCommitAndPush(config.OutputDir, currentDate); err != nil {
-		fmt.Printf("Error with Git operations: %v\n", err)
-		return
+	if err := commitAndPushExports(config, buildCommitMessage(currentDate, summaries)); err != nil {
+		return fmt.Errorf("error with Git operations: %v", err)
 	}
 
 	fmt.Println("Data export and Git operations completed successfully")
+	return nil
 }
 
-// addExportDateToJSON adds an export_date field to the JSON data
-func addExportDateToJSON(data []byte, date string) ([]byte, error) {
-	// Create a wrapper object that contains the data and export date
-	wrapper := map[string]interface{}{
-		"data":        json.RawMessage(data),
-		"export_date": date,
-	}
-
-	// Convert the wrapper back to JSON
-	return json.Marshal(wrapper)
-}
-
-func gitCommitAndPush(repoPath, commitMessage string) error {
-	// Change to the repository directory
-	currentDir, err := os.Getwd()
+// commitAndPushExports commits the exported files and pushes them using the
+// go-git based Repository, falling back to the system git binary when no
+// auth is configured via environment variables.
+func commitAndPushExports(config Config, commitMessage string) error {
+	repo, err := OpenRepository(config.OutputDir)
 	if err != nil {
-		return fmt.Errorf("failed to get current directory: %v", err)
-	}
-
-	// Change to the repository directory
-	if err := os.Chdir(repoPath); err != nil {
-		return fmt.Errorf("failed to change directory: %v", err)
+		return err
 	}
-	defer os.Chdir(currentDir) // Return to original directory when function completes
 
-	// Add all files
-	cmd := exec.Command("git", "add", ".")
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git add failed: %v, output: %s", err, output)
+	committed, err := repo.Commit(commitMessage, config.AuthorName, config.AuthorEmail)
+	if err != nil {
+		return err
 	}
-	fmt.Println("Added files to Git staging area")
-
-	// Commit with date as message
-	commitMsg := fmt.Sprintf("Data export %s", commitMessage)
-	cmd = exec.Command("git", "commit", "-m", commitMsg)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		// Check if it's just "nothing to commit" error
-		outStr := string(output)
-		if !bytes.Contains(output, []byte("nothing to commit")) {
-			return fmt.Errorf("git commit failed: %v, output: %s", err, outStr)
-		}
+	if !committed {
 		fmt.Println("Nothing to commit, working tree clean")
 		return nil
 	}
-	fmt.Printf("Committed changes with message: %s\n", commitMsg)
+	fmt.Printf("Committed changes with message: %s\n", commitMessage)
+
+	auth, err := authFromEnv()
+	if err != nil {
+		return err
+	}
 
-	// Push to remote repository
-	cmd = exec.Command("git", "push")
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git push failed: %v, output: %s", err, output)
+	if err := repo.Push(config.GitRemote, config.GitBranch, auth); err != nil {
+		gitPushErrorsTotal.Inc()
+		return err
 	}
 	fmt.Println("Pushed changes to remote repository")
 
 	return nil
 }
+
+// viewExportSummary records how many rows a view exported, for the commit
+// message's audit trail.
+type viewExportSummary struct {
+	Name string
+	Rows int
+}
+
+// buildCommitMessage renders the git commit message for an export run: a
+// "Data export <date>" subject followed by a per-view row-count summary, so
+// `git log` becomes a useful record of dataset drift instead of an opaque
+// date.
+func buildCommitMessage(date string, summaries []viewExportSummary) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Data export %s\n", date)
+	for _, s := range summaries {
+		fmt.Fprintf(&body, "\n%s: %d rows", s.Name, s.Rows)
+	}
+	return body.String()
+}
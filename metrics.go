@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	exportRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wait_times_export_runs_total",
+		Help: "Total number of export runs, by result.",
+	}, []string{"result"})
+
+	exportRowsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wait_times_export_rows_total",
+		Help: "Total number of rows exported, by view.",
+	}, []string{"view"})
+
+	exportViewErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wait_times_export_view_errors_total",
+		Help: "Total number of errors encountered exporting a view, by view.",
+	}, []string{"view"})
+
+	exportLastSuccessTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "wait_times_export_last_success_timestamp_seconds",
+		Help: "Unix timestamp of the last successful export run.",
+	})
+
+	exportLastDurationSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "wait_times_export_last_duration_seconds",
+		Help: "Duration in seconds of the last export run.",
+	})
+
+	gitPushErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wait_times_export_git_push_errors_total",
+		Help: "Total number of errors pushing exports to the git remote.",
+	})
+)
+
+// serveMetrics starts a Prometheus metrics HTTP server on addr in the
+// background. It is a no-op when addr is empty.
+func serveMetrics(addr string) {
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		fmt.Printf("Serving Prometheus metrics on %s/metrics\n", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Printf("Metrics server error: %v\n", err)
+		}
+	}()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionConfig controls how long date-partitioned exports are kept
+// before pruneExpiredExports deletes them.
+type RetentionConfig struct {
+	Policy     string
+	MaxAgeDays int
+}
+
+// defaultMaxAgeDays maps a RETENTION policy to its default pruning window,
+// used when RETENTION_MAX_AGE_DAYS isn't set explicitly.
+var defaultMaxAgeDays = map[string]int{
+	"daily":   30,
+	"weekly":  180,
+	"monthly": 365,
+}
+
+// loadRetentionConfig reads RETENTION and RETENTION_MAX_AGE_DAYS from the
+// environment, defaulting to a 30-day daily retention window.
+func loadRetentionConfig() (RetentionConfig, error) {
+	policy := getEnvWithDefault("RETENTION", "daily")
+	maxAge, ok := defaultMaxAgeDays[policy]
+	if !ok {
+		return RetentionConfig{}, fmt.Errorf("unknown RETENTION policy %q", policy)
+	}
+
+	if raw := getEnvWithDefault("RETENTION_MAX_AGE_DAYS", ""); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil {
+			return RetentionConfig{}, fmt.Errorf("invalid RETENTION_MAX_AGE_DAYS: %v", err)
+		}
+		maxAge = days
+	}
+
+	return RetentionConfig{Policy: policy, MaxAgeDays: maxAge}, nil
+}
+
+// cadenceWindowDays is how many of the most recent days keep every daily
+// snapshot regardless of policy, so thinning only ever touches history and
+// never the exports still being compared day-to-day.
+const cadenceWindowDays = 7
+
+// periodKey buckets date according to policy's thinning cadence: "weekly"
+// buckets by ISO year-week and "monthly" buckets by calendar month, so that
+// within a bucket only one snapshot is kept. Returns "" for "daily" (and any
+// other policy), meaning every snapshot is kept until it expires.
+func periodKey(policy string, date time.Time) string {
+	switch policy {
+	case "weekly":
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "monthly":
+		return date.Format("2006-01")
+	default:
+		return ""
+	}
+}
+
+// datedExport is a date-partitioned object pending a retention decision.
+type datedExport struct {
+	name string
+	date time.Time
+}
+
+// pruneExpiredExports deletes view's date-partitioned objects (named
+// .../YYYY-MM-DD.ext) that fall outside the retention window, and, for the
+// "weekly"/"monthly" policies, thins anything older than cadenceWindowDays
+// down to one snapshot per week/month. The latest.ext pointer is left
+// untouched.
+func pruneExpiredExports(store Storage, vc ViewConfig, ext string, retention RetentionConfig, now time.Time) error {
+	names, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list exports for %s: %v", vc.Name, err)
+	}
+
+	cutoff := now.AddDate(0, 0, -retention.MaxAgeDays)
+	cadenceCutoff := now.AddDate(0, 0, -cadenceWindowDays)
+	prefix := vc.Name + "/"
+	latest := latestObjectName(vc, ext)
+
+	var survivors []datedExport
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) || name == latest {
+			continue
+		}
+
+		dateStr := strings.TrimSuffix(path.Base(name), "."+ext)
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			continue // not a date-partitioned file; leave it alone
+		}
+
+		if date.Before(cutoff) {
+			if err := store.Delete(name); err != nil {
+				return fmt.Errorf("failed to prune %s: %v", name, err)
+			}
+			fmt.Printf("Pruned expired export %s\n", name)
+			continue
+		}
+
+		survivors = append(survivors, datedExport{name: name, date: date})
+	}
+
+	return thinToCadence(store, retention.Policy, survivors, cadenceCutoff)
+}
+
+// thinToCadence reduces exports older than cadenceCutoff to one per
+// periodKey (week or month, depending on policy), keeping the earliest
+// snapshot in each period and deleting the rest. It is a no-op for the
+// "daily" policy, whose periodKey is always "".
+func thinToCadence(store Storage, policy string, exports []datedExport, cadenceCutoff time.Time) error {
+	kept := make(map[string]datedExport)
+	var toThin []datedExport
+
+	for _, e := range exports {
+		if !e.date.Before(cadenceCutoff) {
+			continue // within the recent window; never thinned
+		}
+
+		key := periodKey(policy, e.date)
+		if key == "" {
+			continue // daily (or unrecognized) policy: keep every snapshot
+		}
+
+		cur, ok := kept[key]
+		if !ok || e.date.Before(cur.date) {
+			if ok {
+				toThin = append(toThin, cur)
+			}
+			kept[key] = e
+		} else {
+			toThin = append(toThin, e)
+		}
+	}
+
+	for _, e := range toThin {
+		if err := store.Delete(e.name); err != nil {
+			return fmt.Errorf("failed to thin %s: %v", e.name, err)
+		}
+		fmt.Printf("Thinned export %s to match %s retention cadence\n", e.name, policy)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/robfig/cron/v3"
+)
+
+// runServe starts the exporter in long-running scheduler mode, running
+// runExport on the schedule defined by EXPORT_CRON (a standard 5-field cron
+// expression, defaulting to daily at 00:05) and blocking until it receives
+// SIGINT/SIGTERM. Overlapping runs are skipped rather than queued.
+func runServe(config Config) error {
+	schedule := getEnvWithDefault("EXPORT_CRON", "5 0 * * *")
+
+	var mu sync.Mutex
+	running := false
+
+	c := cron.New()
+	_, err := c.AddFunc(schedule, func() {
+		mu.Lock()
+		if running {
+			mu.Unlock()
+			fmt.Println("Skipping scheduled run: previous run still in progress")
+			return
+		}
+		running = true
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			running = false
+			mu.Unlock()
+		}()
+
+		fmt.Println("Starting scheduled export run")
+		if err := runExport(config); err != nil {
+			fmt.Printf("Scheduled export run failed: %v\n", err)
+			return
+		}
+		fmt.Println("Scheduled export run completed successfully")
+	})
+	if err != nil {
+		return fmt.Errorf("invalid EXPORT_CRON expression %q: %v", schedule, err)
+	}
+
+	fmt.Printf("Starting scheduler with EXPORT_CRON=%q\n", schedule)
+	c.Start()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+
+	fmt.Println("Received shutdown signal, stopping scheduler")
+	ctx := c.Stop()
+	<-ctx.Done()
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage is a pluggable blob-storage backend for writing (and reading back)
+// exported view data, so the exporter isn't tied to the local filesystem.
+type Storage interface {
+	// Write stores data under name, creating or overwriting it.
+	Write(name string, data []byte) error
+	// List returns the names of all objects currently stored.
+	List() ([]string, error)
+	// Read returns the data previously stored under name.
+	Read(name string) ([]byte, error)
+	// Delete removes the object stored under name.
+	Delete(name string) error
+}
+
+// NewStorage selects a Storage implementation based on the URL-style prefix
+// of addr: "s3://bucket/prefix" for S3, "gs://bucket/prefix" for GCS, or a
+// plain path for the local filesystem.
+func NewStorage(addr string) (Storage, error) {
+	switch {
+	case strings.HasPrefix(addr, "s3://"):
+		return newS3Storage(strings.TrimPrefix(addr, "s3://"))
+	case strings.HasPrefix(addr, "gs://"):
+		return newGCSStorage(strings.TrimPrefix(addr, "gs://"))
+	default:
+		return newLocalStorage(addr)
+	}
+}
+
+// splitBucketAddr splits "bucket/prefix" (as found after the s3:// or gs://
+// scheme) into its bucket and prefix parts.
+func splitBucketAddr(addr string) (bucket, prefix string) {
+	parts := strings.SplitN(addr, "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
+
+// localStorage writes objects as files under a root directory.
+type localStorage struct {
+	root string
+}
+
+func newLocalStorage(root string) (Storage, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create local storage dir %s: %v", root, err)
+	}
+	return &localStorage{root: root}, nil
+}
+
+func (s *localStorage) Write(name string, data []byte) error {
+	path := filepath.Join(s.root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %v", name, err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (s *localStorage) List() ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local storage dir %s: %v", s.root, err)
+	}
+	return names, nil
+}
+
+func (s *localStorage) Read(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.root, name))
+}
+
+func (s *localStorage) Delete(name string) error {
+	if err := os.Remove(filepath.Join(s.root, name)); err != nil {
+		return fmt.Errorf("failed to delete %s: %v", name, err)
+	}
+	return nil
+}
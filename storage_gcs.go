@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// gcsStorage writes objects to a GCS bucket under an optional object prefix.
+type gcsStorage struct {
+	client *storage.Client
+	bucket string
+	prefix string
+}
+
+func newGCSStorage(addr string) (Storage, error) {
+	bucket, prefix := splitBucketAddr(addr)
+
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %v", err)
+	}
+
+	return &gcsStorage{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+func (s *gcsStorage) Write(name string, data []byte) error {
+	ctx := context.Background()
+	w := s.client.Bucket(s.bucket).Object(s.key(name)).NewWriter(ctx)
+
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write gs://%s/%s: %v", s.bucket, s.key(name), err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write gs://%s/%s: %v", s.bucket, s.key(name), err)
+	}
+	return nil
+}
+
+func (s *gcsStorage) List() ([]string, error) {
+	ctx := context.Background()
+	it := s.client.Bucket(s.bucket).Objects(ctx, &storage.Query{Prefix: s.prefix})
+
+	var names []string
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list gs://%s/%s: %v", s.bucket, s.prefix, err)
+		}
+		names = append(names, strings.TrimPrefix(attrs.Name, s.keyPrefix()))
+	}
+	return names, nil
+}
+
+func (s *gcsStorage) Read(name string) ([]byte, error) {
+	ctx := context.Background()
+	r, err := s.client.Bucket(s.bucket).Object(s.key(name)).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gs://%s/%s: %v", s.bucket, s.key(name), err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (s *gcsStorage) Delete(name string) error {
+	ctx := context.Background()
+	if err := s.client.Bucket(s.bucket).Object(s.key(name)).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete gs://%s/%s: %v", s.bucket, s.key(name), err)
+	}
+	return nil
+}
+
+func (s *gcsStorage) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+// keyPrefix returns the prefix to strip from a listed object name to recover
+// the name passed to key(), including the "/" joiner added when prefix is set.
+func (s *gcsStorage) keyPrefix() string {
+	if s.prefix == "" {
+		return ""
+	}
+	return s.prefix + "/"
+}
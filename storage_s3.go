@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3Storage writes objects to an S3 bucket under an optional key prefix.
+type s3Storage struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func newS3Storage(addr string) (Storage, error) {
+	bucket, prefix := splitBucketAddr(addr)
+
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %v", err)
+	}
+
+	return &s3Storage{client: s3.NewFromConfig(cfg), bucket: bucket, prefix: prefix}, nil
+}
+
+func (s *s3Storage) Write(name string, data []byte) error {
+	_, err := s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write s3://%s/%s: %v", s.bucket, s.key(name), err)
+	}
+	return nil
+}
+
+func (s *s3Storage) List() ([]string, error) {
+	var names []string
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %v", s.bucket, s.prefix, err)
+		}
+		for _, obj := range out.Contents {
+			names = append(names, strings.TrimPrefix(aws.ToString(obj.Key), s.keyPrefix()))
+		}
+	}
+
+	return names, nil
+}
+
+func (s *s3Storage) Read(name string) ([]byte, error) {
+	out, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %v", s.bucket, s.key(name), err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+func (s *s3Storage) Delete(name string) error {
+	_, err := s.client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete s3://%s/%s: %v", s.bucket, s.key(name), err)
+	}
+	return nil
+}
+
+func (s *s3Storage) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+// keyPrefix returns the prefix to strip from a listed key to recover the
+// name passed to key(), including the "/" joiner added when prefix is set.
+func (s *s3Storage) keyPrefix() string {
+	if s.prefix == "" {
+		return ""
+	}
+	return s.prefix + "/"
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ViewConfig describes a single export: which database view to query, what
+// format to render it in, and where (and how) to write the result.
+type ViewConfig struct {
+	Name      string `yaml:"name"`
+	Filename  string `yaml:"filename"`
+	Format    string `yaml:"format"`
+	Partition string `yaml:"partition"`
+}
+
+// viewsFile is the top-level shape of views.yaml.
+type viewsFile struct {
+	Views []ViewConfig `yaml:"views"`
+}
+
+// defaultViews mirrors the exports that used to be hard-coded in main,
+// used when no views file is found so the exporter still runs out of the
+// box.
+var defaultViews = []ViewConfig{
+	{Name: "hospital_seven_avg_change", Format: "json"},
+	{Name: "daily_wait_time_stats", Format: "json"},
+	{Name: "monthly_avg_wait_times", Format: "json"},
+}
+
+// loadViews reads the view definitions from path. If path does not exist,
+// it falls back to defaultViews.
+func loadViews(path string) ([]ViewConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return defaultViews, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read views file %s: %v", path, err)
+	}
+
+	var cfg viewsFile
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse views file %s: %v", path, err)
+	}
+	if len(cfg.Views) == 0 {
+		return nil, fmt.Errorf("views file %s defines no views", path)
+	}
+
+	return cfg.Views, nil
+}
+
+// renderObjectName expands a ViewConfig's filename and partition templates
+// (variables: {{.view}}, {{.date}}, {{.year}}, {{.month}}) and joins them
+// into the final object name passed to Storage.Write. When the view sets no
+// Filename, the name defaults to {{.date}}.<ext>; a Filename template is used
+// as given, and ext is not appended to it.
+//
+// By default views are partitioned as <view>/<year>/<month>/<date>.<ext> so
+// retention can prune old snapshots by date; set Partition/Filename to
+// override this for a given view.
+func renderObjectName(vc ViewConfig, date, ext string) (string, error) {
+	data := map[string]string{
+		"view":  vc.Name,
+		"date":  date,
+		"year":  date[0:4],
+		"month": date[5:7],
+	}
+
+	nameTmpl := vc.Filename
+	if nameTmpl == "" {
+		nameTmpl = fmt.Sprintf("{{.date}}.%s", ext)
+	}
+	name, err := renderTemplate(nameTmpl, data)
+	if err != nil {
+		return "", err
+	}
+
+	partitionTmpl := vc.Partition
+	if partitionTmpl == "" {
+		partitionTmpl = "{{.view}}/{{.year}}/{{.month}}"
+	}
+	partition, err := renderTemplate(partitionTmpl, data)
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(partition, name), nil
+}
+
+// latestObjectName returns the object name for a view's always-current
+// copy, kept alongside the date-partitioned history.
+func latestObjectName(vc ViewConfig, ext string) string {
+	return path.Join(vc.Name, fmt.Sprintf("latest.%s", ext))
+}
+
+// renderTemplate executes a text/template string against the given data.
+func renderTemplate(tmpl string, data map[string]string) (string, error) {
+	t, err := template.New("object-name").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %v", tmpl, err)
+	}
+
+	var buf strings.Builder
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %v", tmpl, err)
+	}
+	return buf.String(), nil
+}